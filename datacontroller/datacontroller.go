@@ -0,0 +1,44 @@
+// Package datacontroller defines the storage abstraction used by the
+// data service to read and write user BLOBs. Concrete backends (simple,
+// s3, gcs, ...) live in their own sub-packages and are selected at
+// runtime by service.New based on DataControllerConfig.Type.
+package datacontroller
+
+import (
+	"io"
+	"time"
+)
+
+// DataController is the interface that wraps the basic BLOB
+// operations needed to store and retrieve user data. pathspec is the
+// logical, user-facing path of the BLOB (the {path} URL placeholder),
+// not a location on the underlying storage medium.
+type DataController interface {
+	// UploadBLOB stores the contents read from r at pathspec,
+	// replacing any BLOB already stored there.
+	UploadBLOB(pathspec string, r io.Reader) error
+
+	// DownloadBLOB returns a reader for the contents stored at
+	// pathspec. Callers must Close it when done.
+	DownloadBLOB(pathspec string) (io.ReadCloser, error)
+
+	// StatBLOB returns the size, last-modified time and an ETag for
+	// the BLOB at pathspec, without reading its contents.
+	StatBLOB(pathspec string) (size int64, mtime time.Time, etag string, err error)
+
+	// DownloadBLOBRange returns a reader for at most length bytes of
+	// the BLOB at pathspec, starting at offset. Callers must Close it
+	// when done.
+	DownloadBLOBRange(pathspec string, offset, length int64) (io.ReadCloser, error)
+}
+
+// DigestDownloader is an optional capability implemented by
+// DataControllers that can fetch content directly by its digest,
+// bypassing logical-path lookup entirely (e.g. content-addressable
+// storage backends). Not every DataController supports this; callers
+// should type-assert for it.
+type DigestDownloader interface {
+	// DownloadBLOBDigest returns a reader for the contents stored
+	// under digest. Callers must Close it when done.
+	DownloadBLOBDigest(algo, digest string) (io.ReadCloser, error)
+}
@@ -0,0 +1,84 @@
+// Package gcs implements a datacontroller.DataController backed by
+// Google Cloud Storage.
+package gcs
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/clawio/codes"
+	"google.golang.org/api/option"
+)
+
+// Options holds the configuration needed to create a GCS DataController.
+type Options struct {
+	Bucket              string
+	CredentialsJSONPath string
+}
+
+// GCS is a datacontroller.DataController backed by a Google Cloud
+// Storage bucket. pathspec is used verbatim as the object name.
+type GCS struct {
+	opts   *Options
+	bucket *storage.BucketHandle
+}
+
+// New creates a GCS DataController using the service account
+// credentials at Options.CredentialsJSONPath.
+func New(opts *Options) (*GCS, error) {
+	client, err := storage.NewClient(context.Background(), option.WithCredentialsFile(opts.CredentialsJSONPath))
+	if err != nil {
+		return nil, err
+	}
+	return &GCS{opts: opts, bucket: client.Bucket(opts.Bucket)}, nil
+}
+
+// UploadBLOB streams r to the object using a resumable GCS writer.
+func (g *GCS) UploadBLOB(pathspec string, r io.Reader) error {
+	w := g.bucket.Object(pathspec).NewWriter(context.Background())
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// DownloadBLOB returns a streaming reader for the object.
+func (g *GCS) DownloadBLOB(pathspec string) (io.ReadCloser, error) {
+	r, err := g.bucket.Object(pathspec).NewReader(context.Background())
+	if err != nil {
+		return nil, wrapGCSNotFound(err)
+	}
+	return r, nil
+}
+
+// StatBLOB returns the object's size, mtime and ETag.
+func (g *GCS) StatBLOB(pathspec string) (int64, time.Time, string, error) {
+	attrs, err := g.bucket.Object(pathspec).Attrs(context.Background())
+	if err != nil {
+		return 0, time.Time{}, "", wrapGCSNotFound(err)
+	}
+	return attrs.Size, attrs.Updated, attrs.Etag, nil
+}
+
+// DownloadBLOBRange returns a reader for the [offset, offset+length)
+// byte range of the object.
+func (g *GCS) DownloadBLOBRange(pathspec string, offset, length int64) (io.ReadCloser, error) {
+	r, err := g.bucket.Object(pathspec).NewRangeReader(context.Background(), offset, length)
+	if err != nil {
+		return nil, wrapGCSNotFound(err)
+	}
+	return r, nil
+}
+
+// wrapGCSNotFound maps storage.ErrObjectNotExist to codes.NotFound, so
+// callers get the same not-found semantics regardless of which
+// DataController backend is configured.
+func wrapGCSNotFound(err error) error {
+	if err == storage.ErrObjectNotExist {
+		return codes.NewErr(codes.NotFound, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+// Package mock provides a testify-based mock implementation of
+// datacontroller.DataController for use in service tests.
+package mock
+
+import (
+	"io"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// DataController is a mock datacontroller.DataController.
+type DataController struct {
+	mock.Mock
+}
+
+// UploadBLOB records the call and returns the configured error.
+func (m *DataController) UploadBLOB(pathspec string, r io.Reader) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// DownloadBLOB records the call and returns the configured reader and error.
+func (m *DataController) DownloadBLOB(pathspec string) (io.ReadCloser, error) {
+	args := m.Called()
+	var r io.ReadCloser
+	if args.Get(0) != nil {
+		r = args.Get(0).(io.ReadCloser)
+	}
+	return r, args.Error(1)
+}
+
+// StatBLOB records the call and returns the configured size, mtime, etag and error.
+func (m *DataController) StatBLOB(pathspec string) (int64, time.Time, string, error) {
+	args := m.Called()
+	var mtime time.Time
+	if args.Get(1) != nil {
+		mtime = args.Get(1).(time.Time)
+	}
+	return args.Get(0).(int64), mtime, args.String(2), args.Error(3)
+}
+
+// DownloadBLOBRange records the call and returns the configured reader and error.
+func (m *DataController) DownloadBLOBRange(pathspec string, offset, length int64) (io.ReadCloser, error) {
+	args := m.Called()
+	var r io.ReadCloser
+	if args.Get(0) != nil {
+		r = args.Get(0).(io.ReadCloser)
+	}
+	return r, args.Error(1)
+}
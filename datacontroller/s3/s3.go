@@ -0,0 +1,100 @@
+// Package s3 implements a datacontroller.DataController backed by an
+// S3-compatible object store.
+package s3
+
+import (
+	"io"
+	"time"
+
+	"github.com/clawio/codes"
+	"github.com/minio/minio-go"
+)
+
+// Options holds the configuration needed to create an S3 DataController.
+type Options struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3 is a datacontroller.DataController backed by an S3-compatible
+// object store. pathspec is used verbatim as the object key.
+type S3 struct {
+	opts   *Options
+	client *minio.Client
+}
+
+// New creates an S3 DataController, creating the configured bucket if
+// it does not already exist.
+func New(opts *Options) (*S3, error) {
+	client, err := minio.New(opts.Endpoint, opts.AccessKey, opts.SecretKey, opts.UseSSL)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(opts.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(opts.Bucket, opts.Region); err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3{opts: opts, client: client}, nil
+}
+
+// UploadBLOB streams r into the bucket as a multipart upload; the
+// minio client chooses part size and concurrency automatically.
+func (s *S3) UploadBLOB(pathspec string, r io.Reader) error {
+	_, err := s.client.PutObject(s.opts.Bucket, pathspec, r, -1, minio.PutObjectOptions{})
+	return err
+}
+
+// DownloadBLOB returns a streaming, range-capable reader for the object.
+func (s *S3) DownloadBLOB(pathspec string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(s.opts.Bucket, pathspec, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, wrapS3NotFound(err)
+	}
+	return obj, nil
+}
+
+// StatBLOB returns the object's size, mtime and ETag.
+func (s *S3) StatBLOB(pathspec string) (int64, time.Time, string, error) {
+	info, err := s.client.StatObject(s.opts.Bucket, pathspec, minio.StatObjectOptions{})
+	if err != nil {
+		return 0, time.Time{}, "", wrapS3NotFound(err)
+	}
+	return info.Size, info.LastModified, info.ETag, nil
+}
+
+// DownloadBLOBRange returns a reader for the [offset, offset+length)
+// byte range of the object, served directly by the store via a ranged
+// GET.
+func (s *S3) DownloadBLOBRange(pathspec string, offset, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(s.opts.Bucket, pathspec, opts)
+	if err != nil {
+		return nil, wrapS3NotFound(err)
+	}
+	return obj, nil
+}
+
+// wrapS3NotFound maps minio's "no such key" error responses to
+// codes.NotFound, so callers get the same not-found semantics
+// regardless of which DataController backend is configured.
+func wrapS3NotFound(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NotFound":
+		return codes.NewErr(codes.NotFound, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+package simple
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/clawio/codes"
+)
+
+// casRef is the flat json-per-path reference index entry that maps a
+// logical pathspec to the digest of the content it currently points at.
+type casRef struct {
+	Algo   string `json:"algo"`
+	Digest string `json:"digest"`
+}
+
+// refPath returns the location of the reference index entry for pathspec.
+func refPath(dataDir, pathspec string) string {
+	return filepath.Join(dataDir, pathspec+".ref")
+}
+
+// casBlobPath returns the content-addressed location of a digest,
+// sharded by its first two hex characters to keep directories small.
+func casBlobPath(dataDir, algo, digest string) string {
+	return filepath.Join(dataDir, "cas", algo, digest[:2], digest)
+}
+
+func writeRef(dataDir, pathspec, algo, digest string) error {
+	p := refPath(dataDir, pathspec)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(&casRef{Algo: algo, Digest: digest})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, data, 0644)
+}
+
+func readRef(dataDir, pathspec string) (*casRef, error) {
+	data, err := ioutil.ReadFile(refPath(dataDir, pathspec))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, codes.NewErr(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	ref := &casRef{}
+	if err := json.Unmarshal(data, ref); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// uploadBLOBCAS streams r into a temp file while hashing it, links the
+// temp file into the content-addressed tree (skipping the link if the
+// digest is already present, for dedup), and points pathspec at the
+// resulting digest via the reference index.
+func (s *Simple) uploadBLOBCAS(pathspec string, r io.Reader) error {
+	h, err := newHash(s.opts.CASAlgo)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return errors.New("SimpleCASAlgo must be set when SimpleCASEnabled is true")
+	}
+
+	tmp, err := ioutil.TempFile(s.opts.TempDir, "cas-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(io.MultiWriter(tmp, h), r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	digest := fmt.Sprintf("%x", h.Sum(nil))
+	blobPath := casBlobPath(s.opts.DataDir, s.opts.CASAlgo, digest)
+	if err := os.MkdirAll(filepath.Dir(blobPath), 0755); err != nil {
+		return err
+	}
+	// Link rather than Stat-then-Link: two concurrent uploads of the
+	// same content would otherwise both see IsNotExist and race each
+	// other into Link, and the loser would fail the whole upload
+	// instead of deduping. os.IsExist here means someone else already
+	// stored this digest, which is exactly the dedup we want.
+	if err := os.Link(tmp.Name(), blobPath); err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	return writeRef(s.opts.DataDir, pathspec, s.opts.CASAlgo, digest)
+}
+
+func (s *Simple) downloadBLOBCAS(pathspec string) (io.ReadCloser, error) {
+	ref, err := readRef(s.opts.DataDir, pathspec)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(casBlobPath(s.opts.DataDir, ref.Algo, ref.Digest))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, codes.NewErr(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *Simple) statBLOBCAS(pathspec string) (int64, time.Time, string, error) {
+	ref, err := readRef(s.opts.DataDir, pathspec)
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+	fi, err := os.Stat(casBlobPath(s.opts.DataDir, ref.Algo, ref.Digest))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, time.Time{}, "", codes.NewErr(codes.NotFound, err.Error())
+		}
+		return 0, time.Time{}, "", err
+	}
+	return fi.Size(), fi.ModTime(), ref.Digest, nil
+}
+
+func (s *Simple) downloadBLOBRangeCAS(pathspec string, offset, length int64) (io.ReadCloser, error) {
+	ref, err := readRef(s.opts.DataDir, pathspec)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(casBlobPath(s.opts.DataDir, ref.Algo, ref.Digest))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, codes.NewErr(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return limitedFile{f, io.LimitReader(f, length)}, nil
+}
+
+// DownloadBLOBDigest opens a BLOB directly by its content digest,
+// bypassing the logical-path reference index entirely. It is used by
+// the GET /blob/{digest} endpoint for clients that already know the
+// hash of the content they want.
+func (s *Simple) DownloadBLOBDigest(algo, digest string) (io.ReadCloser, error) {
+	if len(digest) < 2 {
+		return nil, codes.NewErr(codes.NotFound, "digest too short")
+	}
+	f, err := os.Open(casBlobPath(s.opts.DataDir, algo, digest))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, codes.NewErr(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	return f, nil
+}
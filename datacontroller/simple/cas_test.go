@@ -0,0 +1,91 @@
+package simple
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newCASTestOptions(t *testing.T) *Options {
+	dataDir, err := ioutil.TempDir("", "cas-data-")
+	require.Nil(t, err)
+	tempDir, err := ioutil.TempDir("", "cas-temp-")
+	require.Nil(t, err)
+	return &Options{DataDir: dataDir, TempDir: tempDir, CASEnabled: true, CASAlgo: "sha256"}
+}
+
+func TestUploadDownloadBLOBCAS_RoundTrip(t *testing.T) {
+	opts := newCASTestOptions(t)
+	defer os.RemoveAll(opts.DataDir)
+	defer os.RemoveAll(opts.TempDir)
+	s := New(opts)
+
+	content := "hello cas"
+	require.Nil(t, s.UploadBLOB("a/b.txt", strings.NewReader(content)))
+
+	r, err := s.DownloadBLOB("a/b.txt")
+	require.Nil(t, err)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	require.Nil(t, err)
+	require.Equal(t, content, string(data))
+
+	size, _, etag, err := s.StatBLOB("a/b.txt")
+	require.Nil(t, err)
+	require.Equal(t, int64(len(content)), size)
+	require.NotEmpty(t, etag)
+}
+
+func TestUploadBLOBCAS_DedupsIdenticalContent(t *testing.T) {
+	opts := newCASTestOptions(t)
+	defer os.RemoveAll(opts.DataDir)
+	defer os.RemoveAll(opts.TempDir)
+	s := New(opts)
+
+	content := "duplicate me"
+	require.Nil(t, s.UploadBLOB("first.txt", strings.NewReader(content)))
+	require.Nil(t, s.UploadBLOB("second.txt", strings.NewReader(content)))
+
+	refFirst, err := readRef(opts.DataDir, "first.txt")
+	require.Nil(t, err)
+	refSecond, err := readRef(opts.DataDir, "second.txt")
+	require.Nil(t, err)
+	require.Equal(t, refFirst.Digest, refSecond.Digest)
+
+	fi, err := os.Stat(casBlobPath(opts.DataDir, refFirst.Algo, refFirst.Digest))
+	require.Nil(t, err)
+	require.Equal(t, int64(len(content)), fi.Size())
+}
+
+// TestUploadBLOBCAS_ConcurrentUploadsDedup reproduces the TOCTOU window
+// between checking whether a digest is already stored and linking it
+// in: many goroutines racing to store identical content must all
+// succeed, not just the first one to win the race.
+func TestUploadBLOBCAS_ConcurrentUploadsDedup(t *testing.T) {
+	opts := newCASTestOptions(t)
+	defer os.RemoveAll(opts.DataDir)
+	defer os.RemoveAll(opts.TempDir)
+	s := New(opts)
+
+	content := "raced content"
+	const n = 16
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = s.UploadBLOB("concurrent/"+strconv.Itoa(i)+".txt", strings.NewReader(content))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		require.Nil(t, err, "upload %d should not fail due to a concurrent dedup race", i)
+	}
+}
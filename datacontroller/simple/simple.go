@@ -0,0 +1,195 @@
+// Package simple implements a datacontroller.DataController backed
+// directly by the local filesystem: pathspec maps 1:1 to a file under
+// DataDir, except in CAS mode (Options.CASEnabled), where content is
+// stored by digest and pathspec maps to a reference file naming it.
+package simple
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/clawio/codes"
+)
+
+// Options holds the configuration needed to create a Simple
+// DataController.
+type Options struct {
+	DataDir              string
+	TempDir              string
+	Checksum             string
+	VerifyClientChecksum bool
+
+	// CASEnabled switches Simple into content-addressable storage
+	// mode: BLOBs are stored under DataDir by digest instead of by
+	// logical path, and deduplicated across uploads.
+	CASEnabled bool
+	CASAlgo    string
+}
+
+// Simple is a datacontroller.DataController that stores BLOBs as
+// plain files on the local filesystem, under Options.DataDir.
+type Simple struct {
+	opts *Options
+}
+
+// New creates a Simple DataController using the given options.
+func New(opts *Options) *Simple {
+	return &Simple{opts: opts}
+}
+
+// UploadBLOB writes r to a temp file inside TempDir and then renames it
+// into place, so readers never observe a partially written BLOB.
+func (s *Simple) UploadBLOB(pathspec string, r io.Reader) error {
+	if s.opts.CASEnabled {
+		return s.uploadBLOBCAS(pathspec, r)
+	}
+
+	dst := filepath.Join(s.opts.DataDir, pathspec)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(s.opts.TempDir, "upload-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h, err := newHash(s.opts.Checksum)
+	if err != nil {
+		return err
+	}
+	w := io.Writer(tmp)
+	if h != nil {
+		w = io.MultiWriter(tmp, h)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return err
+	}
+	if h != nil {
+		return writeChecksum(dst, s.opts.Checksum, fmt.Sprintf("%x", h.Sum(nil)))
+	}
+	return nil
+}
+
+// DownloadBLOB opens pathspec for reading.
+func (s *Simple) DownloadBLOB(pathspec string) (io.ReadCloser, error) {
+	if s.opts.CASEnabled {
+		return s.downloadBLOBCAS(pathspec)
+	}
+
+	f, err := os.Open(filepath.Join(s.opts.DataDir, pathspec))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, codes.NewErr(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// StatBLOB returns the size, mtime and ETag of pathspec without
+// opening it. The ETag is the checksum computed at upload time when
+// one is available, falling back to size+mtime otherwise.
+func (s *Simple) StatBLOB(pathspec string) (int64, time.Time, string, error) {
+	if s.opts.CASEnabled {
+		return s.statBLOBCAS(pathspec)
+	}
+
+	p := filepath.Join(s.opts.DataDir, pathspec)
+	fi, err := os.Stat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, time.Time{}, "", codes.NewErr(codes.NotFound, err.Error())
+		}
+		return 0, time.Time{}, "", err
+	}
+
+	etag := readChecksum(p)
+	if etag == "" {
+		etag = fmt.Sprintf("%x-%x", fi.Size(), fi.ModTime().UnixNano())
+	}
+	return fi.Size(), fi.ModTime(), etag, nil
+}
+
+// DownloadBLOBRange opens pathspec and returns a reader limited to
+// the [offset, offset+length) byte range.
+func (s *Simple) DownloadBLOBRange(pathspec string, offset, length int64) (io.ReadCloser, error) {
+	if s.opts.CASEnabled {
+		return s.downloadBLOBRangeCAS(pathspec, offset, length)
+	}
+
+	f, err := os.Open(filepath.Join(s.opts.DataDir, pathspec))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, codes.NewErr(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return limitedFile{f, io.LimitReader(f, length)}, nil
+}
+
+// limitedFile pairs a range-limited Reader with the underlying file's
+// Close, so callers of DownloadBLOBRange can Close the returned
+// io.ReadCloser without caring that it's really a truncated view onto
+// an *os.File.
+type limitedFile struct {
+	f *os.File
+	io.Reader
+}
+
+func (l limitedFile) Close() error { return l.f.Close() }
+
+// readChecksum returns the checksum sidecar contents for blobPath, in
+// "algo:hex" form, or "" if no sidecar exists.
+func readChecksum(blobPath string) string {
+	data, err := ioutil.ReadFile(checksumPath(blobPath))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func newHash(algo string) (hash.Hash, error) {
+	switch algo {
+	case "":
+		return nil, nil
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown checksum algorithm %q", algo)
+	}
+}
+
+func checksumPath(blobPath string) string {
+	return blobPath + ".checksum"
+}
+
+func writeChecksum(blobPath, algo, sum string) error {
+	return ioutil.WriteFile(checksumPath(blobPath), []byte(algo+":"+sum), 0644)
+}
@@ -0,0 +1,35 @@
+package transfer
+
+// Exec is an Adapter that hands the client a local helper binary
+// spec (path + args, with "{path}" substituted) to run instead of
+// talking HTTP to the data service, mirroring Git-LFS custom transfer
+// agents.
+type Exec struct {
+	AdapterName string
+	Path        string
+	Args        []string
+}
+
+// Name implements Adapter.
+func (e *Exec) Name() string { return e.AdapterName }
+
+// PrepareUpload implements Adapter.
+func (e *Exec) PrepareUpload(pathspec string) (*Action, error) {
+	return &Action{Kind: "exec", Exec: e.spec(pathspec)}, nil
+}
+
+// PrepareDownload implements Adapter.
+func (e *Exec) PrepareDownload(pathspec string) (*Action, error) {
+	return &Action{Kind: "exec", Exec: e.spec(pathspec)}, nil
+}
+
+func (e *Exec) spec(pathspec string) *ExecSpec {
+	args := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		if a == "{path}" {
+			a = pathspec
+		}
+		args[i] = a
+	}
+	return &ExecSpec{Path: e.Path, Args: args}
+}
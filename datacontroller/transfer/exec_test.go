@@ -0,0 +1,30 @@
+package transfer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExec_PrepareUpload_substitutesPath(t *testing.T) {
+	e := &Exec{AdapterName: "exec", Path: "/usr/bin/clawio-transfer", Args: []string{"put", "{path}", "--bucket", "data"}}
+	action, err := e.PrepareUpload("myblob")
+	require.Nil(t, err)
+	require.Equal(t, "exec", action.Kind)
+	require.Equal(t, "/usr/bin/clawio-transfer", action.Exec.Path)
+	require.Equal(t, []string{"put", "myblob", "--bucket", "data"}, action.Exec.Args)
+}
+
+func TestExec_PrepareDownload_substitutesPath(t *testing.T) {
+	e := &Exec{AdapterName: "exec", Path: "/usr/bin/clawio-transfer", Args: []string{"get", "{path}"}}
+	action, err := e.PrepareDownload("dest/file.txt")
+	require.Nil(t, err)
+	require.Equal(t, []string{"get", "dest/file.txt"}, action.Exec.Args)
+}
+
+func TestExec_spec_leavesNonPlaceholderArgsUntouched(t *testing.T) {
+	e := &Exec{AdapterName: "exec", Path: "/usr/bin/clawio-transfer", Args: []string{"--verbose"}}
+	action, err := e.PrepareUpload("myblob")
+	require.Nil(t, err)
+	require.Equal(t, []string{"--verbose"}, action.Exec.Args)
+}
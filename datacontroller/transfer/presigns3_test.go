@@ -0,0 +1,33 @@
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPresignS3(t *testing.T) *PresignS3 {
+	p, err := NewPresignS3("presign-s3", "s3.example.com", "access-key", "secret-key", true, "my-bucket", time.Minute)
+	require.Nil(t, err)
+	return p
+}
+
+func TestPresignS3_PrepareUpload(t *testing.T) {
+	p := newTestPresignS3(t)
+	action, err := p.PrepareUpload("myblob")
+	require.Nil(t, err)
+	require.Equal(t, "presign-s3", action.Kind)
+	require.Contains(t, action.URL, "s3.example.com")
+	require.Contains(t, action.URL, "my-bucket/myblob")
+	require.True(t, action.ExpiresAt > time.Now().Unix())
+}
+
+func TestPresignS3_PrepareDownload(t *testing.T) {
+	p := newTestPresignS3(t)
+	action, err := p.PrepareDownload("myblob")
+	require.Nil(t, err)
+	require.Equal(t, "presign-s3", action.Kind)
+	require.Contains(t, action.URL, "my-bucket/myblob")
+	require.True(t, action.ExpiresAt > time.Now().Unix())
+}
@@ -0,0 +1,36 @@
+package transfer
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redirect is an Adapter that points the client at a differently
+// hosted copy of the same logical path, e.g. a CDN or a mirror.
+type Redirect struct {
+	AdapterName string
+	// URLTemplate is the target URL with a single "{path}" placeholder.
+	URLTemplate string
+}
+
+// Name implements Adapter.
+func (r *Redirect) Name() string { return r.AdapterName }
+
+// PrepareUpload implements Adapter.
+func (r *Redirect) PrepareUpload(pathspec string) (*Action, error) {
+	return &Action{Kind: "redirect", URL: r.resolve(pathspec)}, nil
+}
+
+// PrepareDownload implements Adapter.
+func (r *Redirect) PrepareDownload(pathspec string) (*Action, error) {
+	return &Action{Kind: "redirect", URL: r.resolve(pathspec)}, nil
+}
+
+// resolve substitutes pathspec into URLTemplate's "{path}" placeholder.
+// pathspec is escaped as a single path segment first, so characters
+// like "?", "#" or "/" can't change the meaning of the generated URL
+// by injecting a query string, truncating at a fragment, or escaping
+// the template's intended path prefix.
+func (r *Redirect) resolve(pathspec string) string {
+	return strings.Replace(r.URLTemplate, "{path}", url.PathEscape(pathspec), 1)
+}
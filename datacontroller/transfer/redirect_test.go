@@ -0,0 +1,30 @@
+package transfer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirect_PrepareUpload(t *testing.T) {
+	r := &Redirect{AdapterName: "redirect", URLTemplate: "https://cdn.example.com/{path}"}
+	action, err := r.PrepareUpload("myblob")
+	require.Nil(t, err)
+	require.Equal(t, "redirect", action.Kind)
+	require.Equal(t, "https://cdn.example.com/myblob", action.URL)
+}
+
+func TestRedirect_PrepareDownload(t *testing.T) {
+	r := &Redirect{AdapterName: "redirect", URLTemplate: "https://cdn.example.com/{path}"}
+	action, err := r.PrepareDownload("myblob")
+	require.Nil(t, err)
+	require.Equal(t, "https://cdn.example.com/myblob", action.URL)
+}
+
+func TestRedirect_resolve_escapesSpecialCharacters(t *testing.T) {
+	r := &Redirect{AdapterName: "redirect", URLTemplate: "https://cdn.example.com/{path}"}
+
+	require.Equal(t, "https://cdn.example.com/a%3Fb", r.resolve("a?b"))
+	require.Equal(t, "https://cdn.example.com/a%23b", r.resolve("a#b"))
+	require.Equal(t, "https://cdn.example.com/..%2F..%2Fetc%2Fpasswd", r.resolve("../../etc/passwd"))
+}
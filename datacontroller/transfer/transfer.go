@@ -0,0 +1,51 @@
+// Package transfer implements Git-LFS-style pluggable transfer
+// adapters: instead of streaming BLOB bytes through the data service,
+// an Adapter describes an out-of-band action (a redirect, a presigned
+// URL, a local helper binary) that lets the client move the bytes
+// directly.
+package transfer
+
+// Action is the JSON envelope returned to a client in place of the
+// BLOB bytes when a non-basic transfer adapter is negotiated.
+type Action struct {
+	// Kind mirrors the adapter's Kind ("redirect", "presign-s3", "exec").
+	Kind string `json:"kind"`
+
+	// URL is set for "redirect" and "presign-s3" actions.
+	URL string `json:"url,omitempty"`
+
+	// ExpiresAt is a Unix timestamp, set when URL is time-limited.
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+
+	// Exec is set for "exec" actions.
+	Exec *ExecSpec `json:"exec,omitempty"`
+}
+
+// ExecSpec describes a local helper binary the client should run to
+// perform the transfer.
+type ExecSpec struct {
+	Path string   `json:"path"`
+	Args []string `json:"args"`
+}
+
+// Adapter is implemented by each transfer backend. pathspec is the
+// logical, user-facing path of the BLOB being uploaded or downloaded.
+type Adapter interface {
+	// Name identifies the adapter; it is what clients send in the
+	// X-Clawio-Transfer request header to opt into it.
+	Name() string
+
+	// PrepareUpload returns the action a client should take to
+	// upload the BLOB at pathspec.
+	PrepareUpload(pathspec string) (*Action, error)
+
+	// PrepareDownload returns the action a client should take to
+	// download the BLOB at pathspec.
+	PrepareDownload(pathspec string) (*Action, error)
+}
+
+// BasicName is the reserved adapter name for the default, in-band
+// transfer that streams bytes through the data service itself. It has
+// no Adapter implementation: negotiating it just means "don't use an
+// adapter".
+const BasicName = "basic"
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/clawio/data/datacontroller"
+	"github.com/gorilla/mux"
+)
+
+var errInvalidDigest = errors.New(`digest must be in "algo:hexdigest" form, with algo one of md5, sha1, sha256`)
+
+// validDigestAlgos mirrors the checksum algorithms simple.newHash
+// supports, since those are the only ones CAS mode ever writes.
+var validDigestAlgos = map[string]bool{
+	"md5":    true,
+	"sha1":   true,
+	"sha256": true,
+}
+
+// DownloadBlob handles GET requests against /blob/{digest} and streams
+// a BLOB back to the client by its content digest, for DataControllers
+// that support content-addressable lookups (see CAS mode in the simple
+// backend). digest is expected in "algo:hexdigest" form, e.g.
+// "sha256:2cf24...".
+func (s *Service) DownloadBlob(w http.ResponseWriter, r *http.Request) {
+	digestDownloader, ok := s.DataController.(datacontroller.DigestDownloader)
+	if !ok {
+		http.Error(w, "this data controller does not support digest lookups", http.StatusNotImplemented)
+		return
+	}
+
+	algo, digest, err := parseDigest(mux.Vars(r)["digest"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reader, err := digestDownloader.DownloadBLOBDigest(algo, digest)
+	if err != nil {
+		writeDataControllerError(w, err)
+		return
+	}
+	defer reader.Close()
+	io.Copy(w, reader)
+}
+
+func parseDigest(raw string) (algo, digest string, err error) {
+	parts := strings.SplitN(raw, ":", 2)
+	if len(parts) != 2 {
+		return "", "", errInvalidDigest
+	}
+	algo, digest = parts[0], parts[1]
+	if !validDigestAlgos[algo] {
+		return "", "", errInvalidDigest
+	}
+	// casBlobPath shards on the first two hex characters of digest, so
+	// anything shorter than that can't be a valid digest.
+	if len(digest) < 2 {
+		return "", "", errInvalidDigest
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", "", errInvalidDigest
+	}
+	return algo, digest, nil
+}
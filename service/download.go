@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/clawio/codes"
+	"github.com/gorilla/mux"
+)
+
+// Download handles GET requests against /download/{path} and streams
+// the requested BLOB back to the client, honoring Range requests and
+// If-None-Match / If-Modified-Since conditional requests.
+func (s *Service) Download(w http.ResponseWriter, r *http.Request) {
+	pathspec := mux.Vars(r)["path"]
+
+	if s.negotiateTransfer(w, r, pathspec, false) {
+		return
+	}
+
+	size, mtime, etag, err := s.DataController.StatBLOB(pathspec)
+	if err != nil {
+		writeDataControllerError(w, err)
+		return
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", mtime.UTC().Format(http.TimeFormat))
+
+	if isNotModified(r, etag, mtime) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rangeHeader := r.Header.Get("Range")
+	if rangeHeader == "" {
+		reader, err := s.DataController.DownloadBLOB(pathspec)
+		if err != nil {
+			writeDataControllerError(w, err)
+			return
+		}
+		defer reader.Close()
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		if _, err := io.Copy(w, reader); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	ranges, err := parseRange(rangeHeader, size)
+	if err != nil {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		http.Error(w, err.Error(), http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+
+	if len(ranges) == 1 {
+		s.downloadSingleRange(w, pathspec, ranges[0], size)
+		return
+	}
+	s.downloadMultiRange(w, pathspec, ranges, size)
+}
+
+func (s *Service) downloadSingleRange(w http.ResponseWriter, pathspec string, ra httpRange, size int64) {
+	reader, err := s.DataController.DownloadBLOBRange(pathspec, ra.start, ra.length)
+	if err != nil {
+		writeDataControllerError(w, err)
+		return
+	}
+	defer reader.Close()
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", ra.start, ra.start+ra.length-1, size))
+	w.Header().Set("Content-Length", strconv.FormatInt(ra.length, 10))
+	w.WriteHeader(http.StatusPartialContent)
+	io.Copy(w, reader)
+}
+
+func (s *Service) downloadMultiRange(w http.ResponseWriter, pathspec string, ranges []httpRange, size int64) {
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+	w.WriteHeader(http.StatusPartialContent)
+	defer mw.Close()
+
+	for _, ra := range ranges {
+		reader, err := s.DataController.DownloadBLOBRange(pathspec, ra.start, ra.length)
+		if err != nil {
+			return
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", ra.start, ra.start+ra.length-1, size)},
+		})
+		if err != nil {
+			reader.Close()
+			return
+		}
+		io.Copy(part, reader)
+		reader.Close()
+	}
+}
+
+// isNotModified reports whether r's conditional request headers are
+// satisfied by the resource's current etag/mtime, per RFC 7232.
+// If-None-Match takes precedence over If-Modified-Since when both are
+// present. If-None-Match is matched only as a single exact value or
+// "*"; lists of entity tags and weak comparison are not handled.
+func isNotModified(r *http.Request, etag string, mtime time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return inm == etag || inm == "*"
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !mtime.After(t)
+	}
+	return false
+}
+
+// writeDataControllerError maps a DataController error to an HTTP
+// status code, using codes.NotFound to distinguish missing BLOBs.
+func writeDataControllerError(w http.ResponseWriter, err error) {
+	if codeErr, ok := err.(*codes.Err); ok && codeErr.Code == codes.NotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
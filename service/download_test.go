@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/clawio/codes"
 	"github.com/stretchr/testify/require"
@@ -18,8 +19,13 @@ func (m *errorReader) Read(p []byte) (n int, err error) {
 	return 0, errors.New("test error")
 }
 
+func (m *errorReader) Close() error {
+	return nil
+}
+
 func (suite *TestSuite) TestDownload() {
-	reader := strings.NewReader("1")
+	reader := ioutil.NopCloser(strings.NewReader("1"))
+	suite.MockDataController.On("StatBLOB").Once().Return(int64(1), time.Now(), "etag", nil)
 	suite.MockDataController.On("DownloadBLOB").Once().Return(reader, nil)
 	r, err := http.NewRequest("GET", downloadURL+"myblob", nil)
 	setToken(r)
@@ -32,8 +38,7 @@ func (suite *TestSuite) TestDownload() {
 	require.Equal(suite.T(), "1", string(data))
 }
 func (suite *TestSuite) TestDownload_withCodeNotFound() {
-	reader := strings.NewReader("1")
-	suite.MockDataController.On("DownloadBLOB").Once().Return(reader, codes.NewErr(codes.NotFound, ""))
+	suite.MockDataController.On("StatBLOB").Once().Return(int64(0), time.Time{}, "", codes.NewErr(codes.NotFound, ""))
 	r, err := http.NewRequest("GET", downloadURL+"myblob", nil)
 	setToken(r)
 	require.Nil(suite.T(), err)
@@ -43,8 +48,7 @@ func (suite *TestSuite) TestDownload_withCodeNotFound() {
 	require.Equal(suite.T(), http.StatusNotFound, w.Code)
 }
 func (suite *TestSuite) TestDownload_withError() {
-	reader := strings.NewReader("1")
-	suite.MockDataController.On("DownloadBLOB").Once().Return(reader, errors.New("some error"))
+	suite.MockDataController.On("StatBLOB").Once().Return(int64(0), time.Time{}, "", errors.New("some error"))
 	r, err := http.NewRequest("GET", downloadURL+"myblob", nil)
 	setToken(r)
 	require.Nil(suite.T(), err)
@@ -54,6 +58,7 @@ func (suite *TestSuite) TestDownload_withError() {
 	require.Equal(suite.T(), http.StatusInternalServerError, w.Code)
 }
 func (suite *TestSuite) TestDownload_withErrorCopying() {
+	suite.MockDataController.On("StatBLOB").Once().Return(int64(1), time.Now(), "etag", nil)
 	suite.MockDataController.On("DownloadBLOB").Once().Return(&errorReader{}, nil)
 	r, err := http.NewRequest("GET", downloadURL+"myblob", nil)
 	setToken(r)
@@ -63,3 +68,29 @@ func (suite *TestSuite) TestDownload_withErrorCopying() {
 	suite.Server.ServeHTTP(w, r)
 	require.Equal(suite.T(), http.StatusInternalServerError, w.Code)
 }
+func (suite *TestSuite) TestDownload_withRange() {
+	reader := ioutil.NopCloser(strings.NewReader("23"))
+	suite.MockDataController.On("StatBLOB").Once().Return(int64(4), time.Now(), "etag", nil)
+	suite.MockDataController.On("DownloadBLOBRange").Once().Return(reader, nil)
+	r, err := http.NewRequest("GET", downloadURL+"myblob", nil)
+	require.Nil(suite.T(), err)
+	r.Header.Set("Range", "bytes=2-3")
+	setToken(r)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, r)
+	require.Equal(suite.T(), http.StatusPartialContent, w.Code)
+	require.Equal(suite.T(), "bytes 2-3/4", w.Header().Get("Content-Range"))
+	data, err := ioutil.ReadAll(w.Body)
+	require.Nil(suite.T(), err)
+	require.Equal(suite.T(), "23", string(data))
+}
+func (suite *TestSuite) TestDownload_withIfNoneMatch() {
+	suite.MockDataController.On("StatBLOB").Once().Return(int64(1), time.Now(), "etag", nil)
+	r, err := http.NewRequest("GET", downloadURL+"myblob", nil)
+	require.Nil(suite.T(), err)
+	r.Header.Set("If-None-Match", "etag")
+	setToken(r)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, r)
+	require.Equal(suite.T(), http.StatusNotModified, w.Code)
+}
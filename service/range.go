@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// httpRange represents a single byte range parsed from a Range header,
+// already resolved against the resource's size.
+type httpRange struct {
+	start  int64
+	length int64
+}
+
+// errNoOverlap is returned by parseRange when none of the requested
+// ranges overlap the resource.
+var errNoOverlap = errors.New("invalid range: failed to overlap")
+
+// maxRanges bounds the number of ranges accepted in a single Range
+// header, so a request with thousands of comma-separated ranges can't
+// force thousands of DownloadBLOBRange calls for one response.
+const maxRanges = 1024
+
+// parseRange parses a Range header value, e.g. "bytes=0-499,-500", per
+// RFC 7233. size is the total size of the resource being ranged over.
+func parseRange(header string, size int64) ([]httpRange, error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, errors.New("invalid range: does not start with bytes=")
+	}
+
+	var ranges []httpRange
+	noOverlap := false
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		i := strings.Index(spec, "-")
+		if i < 0 {
+			return nil, errors.New("invalid range")
+		}
+		startStr, endStr := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+		var r httpRange
+		if startStr == "" {
+			// suffix range: "-N" means the last N bytes.
+			n, err := strconv.ParseInt(endStr, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if n > size {
+				n = size
+			}
+			r.start = size - n
+			r.length = n
+		} else {
+			start, err := strconv.ParseInt(startStr, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if start >= size {
+				noOverlap = true
+				continue
+			}
+			r.start = start
+			if endStr == "" {
+				r.length = size - start
+			} else {
+				end, err := strconv.ParseInt(endStr, 10, 64)
+				if err != nil || end < start {
+					return nil, errors.New("invalid range")
+				}
+				if end >= size {
+					end = size - 1
+				}
+				r.length = end - start + 1
+			}
+		}
+		ranges = append(ranges, r)
+		if len(ranges) > maxRanges {
+			return nil, errors.New("invalid range: too many ranges")
+		}
+	}
+
+	if noOverlap && len(ranges) == 0 {
+		return nil, errNoOverlap
+	}
+	return ranges, nil
+}
@@ -2,13 +2,18 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/NYTimes/gizmo/config"
 	"github.com/clawio/authentication/lib"
 	"github.com/clawio/data/datacontroller"
+	"github.com/clawio/data/datacontroller/gcs"
+	"github.com/clawio/data/datacontroller/s3"
 	"github.com/clawio/data/datacontroller/simple"
+	"github.com/clawio/data/datacontroller/transfer"
 	"github.com/clawio/sdk"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -17,17 +22,20 @@ type (
 	// Service implements server.Service and
 	// handle all requests to the server.
 	Service struct {
-		Config         *Config
-		SDK            *sdk.SDK
-		DataController datacontroller.DataController
+		Config           *Config
+		SDK              *sdk.SDK
+		DataController   datacontroller.DataController
+		TransferAdapters map[string]transfer.Adapter
 	}
 
 	// Config is a struct that holds the
 	// configuration for Service
 	Config struct {
-		Server         *config.Server
-		General        *GeneralConfig
-		DataController *DataControllerConfig
+		Server           *config.Server
+		General          *GeneralConfig
+		DataController   *DataControllerConfig
+		Tus              *TusConfig
+		TransferAdapters []*AdapterConfig
 	}
 
 	// GeneralConfig contains configuration parameters
@@ -47,9 +55,54 @@ type (
 		SimpleTempDir              string
 		SimpleChecksum             string
 		SimpleVerifyClientChecksum bool
+		SimpleCASEnabled           bool
+		SimpleCASAlgo              string
+		S3Endpoint                 string
+		S3Bucket                   string
+		S3Region                   string
+		S3AccessKey                string
+		S3SecretKey                string
+		S3UseSSL                   bool
+		GCSBucket                  string
+		GCSCredentialsJSONPath     string
+	}
+
+	// TusConfig is a struct that holds configuration parameters
+	// for the resumable (tus.io) upload subsystem.
+	TusConfig struct {
+		// UploadExpirySeconds is how long an incomplete upload is
+		// kept before it is considered stale and garbage collected.
+		// Defaults to defaultTusUploadExpiry when zero.
+		UploadExpirySeconds int64
+	}
+
+	// AdapterConfig configures a single pluggable transfer adapter,
+	// negotiated by clients via the X-Clawio-Transfer request header.
+	AdapterConfig struct {
+		Name string
+		Kind string // "redirect", "presign-s3" or "exec"
+
+		// redirect
+		RedirectURLTemplate string
+
+		// exec
+		ExecPath string
+		ExecArgs []string
+
+		// presign-s3
+		S3Endpoint     string
+		S3Bucket       string
+		S3AccessKey    string
+		S3SecretKey    string
+		S3UseSSL       bool
+		PresignTTLSecs int64
 	}
 )
 
+// defaultTusUploadExpiry is used when TusConfig.UploadExpirySeconds is
+// not set.
+const defaultTusUploadExpiry = 24 * 60 * 60 // 24h
+
 // New will instantiate and return
 // a new Service that implements server.Service.
 func New(cfg *Config) (*Service, error) {
@@ -71,17 +124,80 @@ func New(cfg *Config) (*Service, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Service{Config: cfg, SDK: s, DataController: dataController}, nil
+
+	adapters, err := getTransferAdapters(cfg.TransferAdapters)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &Service{Config: cfg, SDK: s, DataController: dataController, TransferAdapters: adapters}
+	svc.startTusGC(time.Hour)
+	return svc, nil
+}
+
+// getTransferAdapters builds the adapter registry from config, keyed
+// by adapter name. The "basic" in-band transfer is always available
+// and does not need a config entry.
+func getTransferAdapters(cfgs []*AdapterConfig) (map[string]transfer.Adapter, error) {
+	adapters := map[string]transfer.Adapter{}
+	for _, c := range cfgs {
+		var (
+			adapter transfer.Adapter
+			err     error
+		)
+		switch c.Kind {
+		case "redirect":
+			adapter = &transfer.Redirect{AdapterName: c.Name, URLTemplate: c.RedirectURLTemplate}
+		case "exec":
+			adapter = &transfer.Exec{AdapterName: c.Name, Path: c.ExecPath, Args: c.ExecArgs}
+		case "presign-s3":
+			ttl := time.Duration(c.PresignTTLSecs) * time.Second
+			adapter, err = transfer.NewPresignS3(c.Name, c.S3Endpoint, c.S3AccessKey, c.S3SecretKey, c.S3UseSSL, c.S3Bucket, ttl)
+		default:
+			err = fmt.Errorf("unknown transfer adapter kind %q", c.Kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		adapters[c.Name] = adapter
+	}
+	return adapters, nil
 }
 
 func getDataController(cfg *DataControllerConfig) (datacontroller.DataController, error) {
+	switch cfg.Type {
+	case "", "simple":
+		return getSimpleDataController(cfg)
+	case "s3":
+		return s3.New(&s3.Options{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	case "gcs":
+		return gcs.New(&gcs.Options{
+			Bucket:              cfg.GCSBucket,
+			CredentialsJSONPath: cfg.GCSCredentialsJSONPath,
+		})
+	default:
+		return nil, fmt.Errorf("unknown data controller type %q", cfg.Type)
+	}
+}
+
+// getSimpleDataController builds the local-filesystem backend. Unlike
+// the cloud backends, it needs DataDir and TempDir to exist upfront.
+func getSimpleDataController(cfg *DataControllerConfig) (datacontroller.DataController, error) {
 	opts := &simple.Options{
 		DataDir:              cfg.SimpleDataDir,
 		TempDir:              cfg.SimpleTempDir,
 		Checksum:             cfg.SimpleChecksum,
 		VerifyClientChecksum: cfg.SimpleVerifyClientChecksum,
+		CASEnabled:           cfg.SimpleCASEnabled,
+		CASAlgo:              cfg.SimpleCASAlgo,
 	}
-	// create DataDir and TempDir
 	if err := os.MkdirAll(opts.DataDir, 0755); err != nil {
 		return nil, err
 	}
@@ -119,8 +235,18 @@ func (s *Service) Endpoints() map[string]map[string]http.HandlerFunc {
 		"/upload/{path:.*}": {
 			"PUT": prometheus.InstrumentHandlerFunc("/upload", authenticator.JWTHandlerFunc(s.Upload)),
 		},
+		"/upload": {
+			"POST": prometheus.InstrumentHandlerFunc("/upload", authenticator.JWTHandlerFunc(s.CreateUpload)),
+		},
+		"/upload/{id}": {
+			"HEAD":  prometheus.InstrumentHandlerFunc("/upload", authenticator.JWTHandlerFunc(s.HeadUpload)),
+			"PATCH": prometheus.InstrumentHandlerFunc("/upload", authenticator.JWTHandlerFunc(s.PatchUpload)),
+		},
 		"/download/{path:.*}": {
 			"GET": prometheus.InstrumentHandlerFunc("/download", authenticator.JWTHandlerFunc(s.Download)),
 		},
+		"/blob/{digest}": {
+			"GET": prometheus.InstrumentHandlerFunc("/blob", authenticator.JWTHandlerFunc(s.DownloadBlob)),
+		},
 	}
 }
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/clawio/data/datacontroller/transfer"
+)
+
+// negotiateTransfer inspects the X-Clawio-Transfer request header and,
+// if the client asked for a configured non-basic adapter, writes the
+// adapter's action envelope in place of streaming BLOB bytes. It
+// returns true when it has handled the response and the caller should
+// not proceed with the in-band transfer.
+func (s *Service) negotiateTransfer(w http.ResponseWriter, r *http.Request, pathspec string, upload bool) bool {
+	name := r.Header.Get("X-Clawio-Transfer")
+	if name == "" || name == transfer.BasicName {
+		return false
+	}
+
+	adapter, ok := s.TransferAdapters[name]
+	if !ok {
+		// Client asked for something we don't support: fall back to
+		// the basic in-band transfer rather than failing the request.
+		return false
+	}
+
+	var (
+		action *transfer.Action
+		err    error
+	)
+	if upload {
+		action, err = adapter.PrepareUpload(pathspec)
+	} else {
+		action, err = adapter.PrepareDownload(pathspec)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return true
+	}
+
+	w.Header().Set("X-Clawio-Transfer", name)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(action)
+	return true
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/clawio/data/datacontroller/transfer"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *TestSuite) TestUpload_withTransferAdapter() {
+	suite.Service.TransferAdapters = map[string]transfer.Adapter{
+		"redirect": &transfer.Redirect{AdapterName: "redirect", URLTemplate: "https://cdn.example.com/{path}"},
+	}
+	r, err := http.NewRequest("PUT", uploadURL+"myblob", nil)
+	require.Nil(suite.T(), err)
+	r.Header.Set("X-Clawio-Transfer", "redirect")
+	setToken(r)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, r)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	require.Equal(suite.T(), "redirect", w.Header().Get("X-Clawio-Transfer"))
+	require.Contains(suite.T(), w.Body.String(), "https://cdn.example.com/myblob")
+}
+
+func (suite *TestSuite) TestUpload_withUnknownTransferAdapterFallsBackToBasic() {
+	suite.MockDataController.On("UploadBLOB").Once().Return(nil)
+	r, err := http.NewRequest("PUT", uploadURL+"myblob", nil)
+	require.Nil(suite.T(), err)
+	r.Header.Set("X-Clawio-Transfer", "nonexistent")
+	setToken(r)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, r)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// tusResumableVersion is the protocol version advertised via the
+// Tus-Resumable header, as required by the tus.io spec.
+const tusResumableVersion = "1.0.0"
+
+type contextKey string
+
+// userContextKey is the request context key under which the
+// authenticated username is stored by lib.Authenticator.
+const userContextKey contextKey = "user"
+
+// tusUpload is the on-disk, persisted state for a single in-progress
+// resumable upload. It lives under SimpleTempDir so uploads survive a
+// service restart.
+type tusUpload struct {
+	ID        string            `json:"id"`
+	Path      string            `json:"path"`
+	User      string            `json:"user"`
+	Offset    int64             `json:"offset"`
+	Length    int64             `json:"length"`
+	Metadata  map[string]string `json:"metadata"`
+	UpdatedAt int64             `json:"updated_at"`
+}
+
+// sanitizeUploadPath cleans a client-supplied logical path so it can't
+// escape DataDir once joined onto it. Prefixing with "/" before
+// path.Clean neutralizes ".." the same way net/http's file server
+// does: the result can never climb above the root.
+func sanitizeUploadPath(raw string) (string, error) {
+	if raw == "" {
+		return "", errors.New("Upload-Metadata must include a filename")
+	}
+	clean := strings.TrimPrefix(path.Clean("/"+raw), "/")
+	if clean == "" {
+		return "", errors.New("filename must not escape the data directory")
+	}
+	return clean, nil
+}
+
+func (s *Service) tusDir() string {
+	return filepath.Join(s.Config.DataController.SimpleTempDir, "tus")
+}
+
+func (s *Service) tusStatePath(id string) string {
+	return filepath.Join(s.tusDir(), id+".json")
+}
+
+func (s *Service) tusDataPath(id string) string {
+	return filepath.Join(s.tusDir(), id+".data")
+}
+
+func (s *Service) tusExpiry() time.Duration {
+	ttl := defaultTusUploadExpiry
+	if s.Config.Tus != nil && s.Config.Tus.UploadExpirySeconds > 0 {
+		ttl = int(s.Config.Tus.UploadExpirySeconds)
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+func (s *Service) loadTusUpload(id string) (*tusUpload, error) {
+	data, err := ioutil.ReadFile(s.tusStatePath(id))
+	if err != nil {
+		return nil, err
+	}
+	u := &tusUpload{}
+	if err := json.Unmarshal(data, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (s *Service) saveTusUpload(u *tusUpload) error {
+	u.UpdatedAt = time.Now().Unix()
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.tusStatePath(u.ID), data, 0644)
+}
+
+func (s *Service) removeTusUpload(id string) {
+	os.Remove(s.tusStatePath(id))
+	os.Remove(s.tusDataPath(id))
+}
+
+// CreateUpload handles POST /upload and creates a new resumable
+// upload resource, per the tus.io creation extension.
+func (s *Service) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		http.Error(w, "invalid or missing Upload-Length", http.StatusBadRequest)
+		return
+	}
+
+	metadata := parseTusMetadata(r.Header.Get("Upload-Metadata"))
+	uploadPath, err := sanitizeUploadPath(metadata["filename"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := os.MkdirAll(s.tusDir(), 0755); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id, err := newTusID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	f, err := os.Create(s.tusDataPath(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	f.Close()
+
+	u := &tusUpload{ID: id, Path: uploadPath, User: requestUser(r), Length: length, Metadata: metadata}
+	if err := s.saveTusUpload(u); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Tus-Resumable", tusResumableVersion)
+	w.Header().Set("Location", path.Join(s.Prefix(), "upload", id))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HeadUpload handles HEAD /upload/{id} and reports how much of the
+// upload has been received so far.
+func (s *Service) HeadUpload(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	u, err := s.loadTusUpload(id)
+	if err != nil || u.User != requestUser(r) {
+		http.Error(w, "upload not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Tus-Resumable", tusResumableVersion)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(u.Length, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// PatchUpload handles PATCH /upload/{id} and appends a chunk to the
+// upload, committing it to the DataController once fully received.
+func (s *Service) PatchUpload(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	u, err := s.loadTusUpload(id)
+	if err != nil || u.User != requestUser(r) {
+		http.Error(w, "upload not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		http.Error(w, "Content-Type must be application/offset+octet-stream", http.StatusBadRequest)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid Upload-Offset", http.StatusBadRequest)
+		return
+	}
+	if offset != u.Offset {
+		http.Error(w, "Upload-Offset does not match current offset", http.StatusConflict)
+		return
+	}
+
+	f, err := os.OpenFile(s.tusDataPath(id), os.O_WRONLY, 0644)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	remaining := u.Length - u.Offset
+	written, err := io.Copy(f, io.LimitReader(r.Body, remaining))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if written == remaining {
+		// The body may still hold bytes past what the upload declared;
+		// a non-empty read here means the client sent more than fits.
+		var extra [1]byte
+		if n, _ := r.Body.Read(extra[:]); n > 0 {
+			http.Error(w, "request body exceeds Upload-Length", http.StatusConflict)
+			return
+		}
+	}
+
+	u.Offset += written
+	if err := s.saveTusUpload(u); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if u.Offset >= u.Length {
+		if err := s.commitTusUpload(u); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.removeTusUpload(id)
+	}
+
+	w.Header().Set("Tus-Resumable", tusResumableVersion)
+	w.Header().Set("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// commitTusUpload moves the fully-assembled upload to its logical
+// path via the DataController.
+func (s *Service) commitTusUpload(u *tusUpload) error {
+	f, err := os.Open(s.tusDataPath(u.ID))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return s.DataController.UploadBLOB(u.Path, f)
+}
+
+// gcTusUploads removes upload state and partial data for uploads that
+// have not been touched within the configured TTL.
+func (s *Service) gcTusUploads() {
+	ttl := s.tusExpiry()
+	entries, err := ioutil.ReadDir(s.tusDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		u, err := s.loadTusUpload(id)
+		if err != nil {
+			continue
+		}
+		if time.Since(time.Unix(u.UpdatedAt, 0)) > ttl {
+			s.removeTusUpload(id)
+		}
+	}
+}
+
+// startTusGC periodically reaps stale uploads. The reaper goroutine
+// runs for the life of the process; nothing stops it. Each call starts
+// another reaper, which is harmless since removal is idempotent.
+func (s *Service) startTusGC(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.gcTusUploads()
+		}
+	}()
+}
+
+func newTusID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", b), nil
+}
+
+// parseTusMetadata decodes the Upload-Metadata header: a comma
+// separated list of "key base64(value)" pairs.
+func parseTusMetadata(header string) map[string]string {
+	metadata := map[string]string{}
+	if header == "" {
+		return metadata
+	}
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			continue
+		}
+		metadata[parts[0]] = string(value)
+	}
+	return metadata
+}
+
+// requestUser returns the authenticated username for r, or "" if the
+// request carries none.
+func requestUser(r *http.Request) string {
+	if u, ok := r.Context().Value(userContextKey).(string); ok {
+		return u
+	}
+	return ""
+}
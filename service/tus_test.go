@@ -0,0 +1,178 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clawio/authentication/lib"
+	"github.com/clawio/entities"
+	"github.com/stretchr/testify/require"
+)
+
+// tusMetadataHeader builds an Upload-Metadata header value from a set
+// of key/value pairs, the inverse of parseTusMetadata.
+func tusMetadataHeader(kv map[string]string) string {
+	parts := make([]string, 0, len(kv))
+	for k, v := range kv {
+		parts = append(parts, k+" "+base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+	return strings.Join(parts, ",")
+}
+
+// tokenForUser mints a real, valid JWT for username, distinct from the
+// shared jwtToken used by setToken, so ownership checks can be
+// exercised against a genuinely different authenticated caller rather
+// than a hand-written tusUpload.User value.
+func (suite *TestSuite) tokenForUser(username string) string {
+	authenticator := lib.NewAuthenticator(suite.Service.Config.General.JWTKey, suite.Service.Config.General.JWTSigningMethod)
+	token, err := authenticator.CreateToken(&entities.User{Username: username})
+	require.Nil(suite.T(), err)
+	return token
+}
+
+func setBearerToken(r *http.Request, token string) {
+	r.Header.Set("Authorization", "bearer "+token)
+}
+
+func (suite *TestSuite) createTusUploadAs(token string, length int, filename string) string {
+	r, err := http.NewRequest("POST", strings.TrimSuffix(uploadURL, "/"), nil)
+	require.Nil(suite.T(), err)
+	r.Header.Set("Upload-Length", strconv.Itoa(length))
+	r.Header.Set("Upload-Metadata", tusMetadataHeader(map[string]string{"filename": filename}))
+	setBearerToken(r, token)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, r)
+	require.Equal(suite.T(), http.StatusCreated, w.Code)
+	return path.Base(w.Header().Get("Location"))
+}
+
+func (suite *TestSuite) createTusUpload(length int, filename string) string {
+	return suite.createTusUploadAs(jwtToken, length, filename)
+}
+
+func (suite *TestSuite) TestCreateHeadPatchUpload() {
+	content := "hello"
+	id := suite.createTusUpload(len(content), "dest/file.txt")
+
+	headReq, err := http.NewRequest("HEAD", uploadURL+id, nil)
+	require.Nil(suite.T(), err)
+	setToken(headReq)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, headReq)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	require.Equal(suite.T(), "0", w.Header().Get("Upload-Offset"))
+	require.Equal(suite.T(), strconv.Itoa(len(content)), w.Header().Get("Upload-Length"))
+
+	suite.MockDataController.On("UploadBLOB").Once().Return(nil)
+	patchReq, err := http.NewRequest("PATCH", uploadURL+id, strings.NewReader(content))
+	require.Nil(suite.T(), err)
+	patchReq.Header.Set("Content-Type", "application/offset+octet-stream")
+	patchReq.Header.Set("Upload-Offset", "0")
+	setToken(patchReq)
+	w = httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, patchReq)
+	require.Equal(suite.T(), http.StatusNoContent, w.Code)
+	require.Equal(suite.T(), strconv.Itoa(len(content)), w.Header().Get("Upload-Offset"))
+	suite.MockDataController.AssertExpectations(suite.T())
+
+	// the upload is removed once fully committed.
+	headReq2, err := http.NewRequest("HEAD", uploadURL+id, nil)
+	require.Nil(suite.T(), err)
+	setToken(headReq2)
+	w = httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, headReq2)
+	require.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *TestSuite) TestPatchUpload_withExcessBody() {
+	id := suite.createTusUpload(3, "dest/short.txt")
+
+	r, err := http.NewRequest("PATCH", uploadURL+id, strings.NewReader("toolong"))
+	require.Nil(suite.T(), err)
+	r.Header.Set("Content-Type", "application/offset+octet-stream")
+	r.Header.Set("Upload-Offset", "0")
+	setToken(r)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, r)
+	require.Equal(suite.T(), http.StatusConflict, w.Code)
+}
+
+func (suite *TestSuite) TestHeadUpload_withWrongUser() {
+	require.Nil(suite.T(), os.MkdirAll(suite.Service.tusDir(), 0755))
+	u := &tusUpload{ID: "wrongUserHead", Path: "p", User: "someone-else", Length: 1}
+	require.Nil(suite.T(), suite.Service.saveTusUpload(u))
+	defer suite.Service.removeTusUpload(u.ID)
+
+	r, err := http.NewRequest("HEAD", uploadURL+u.ID, nil)
+	require.Nil(suite.T(), err)
+	setToken(r)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, r)
+	require.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *TestSuite) TestPatchUpload_withWrongUser() {
+	require.Nil(suite.T(), os.MkdirAll(suite.Service.tusDir(), 0755))
+	u := &tusUpload{ID: "wrongUserPatch", Path: "p", User: "someone-else", Length: 1}
+	require.Nil(suite.T(), suite.Service.saveTusUpload(u))
+	defer suite.Service.removeTusUpload(u.ID)
+
+	r, err := http.NewRequest("PATCH", uploadURL+u.ID, strings.NewReader("x"))
+	require.Nil(suite.T(), err)
+	r.Header.Set("Content-Type", "application/offset+octet-stream")
+	r.Header.Set("Upload-Offset", "0")
+	setToken(r)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, r)
+	require.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *TestSuite) TestHeadPatchUpload_withDistinctAuthenticatedUser() {
+	id := suite.createTusUploadAs(jwtToken, 5, "dest/owned.txt")
+	otherToken := suite.tokenForUser("someone-else")
+
+	headOther, err := http.NewRequest("HEAD", uploadURL+id, nil)
+	require.Nil(suite.T(), err)
+	setBearerToken(headOther, otherToken)
+	w := httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, headOther)
+	require.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	patchOther, err := http.NewRequest("PATCH", uploadURL+id, strings.NewReader("hello"))
+	require.Nil(suite.T(), err)
+	patchOther.Header.Set("Content-Type", "application/offset+octet-stream")
+	patchOther.Header.Set("Upload-Offset", "0")
+	setBearerToken(patchOther, otherToken)
+	w = httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, patchOther)
+	require.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	// the owning user's own token must still work against the same upload.
+	headOwner, err := http.NewRequest("HEAD", uploadURL+id, nil)
+	require.Nil(suite.T(), err)
+	setToken(headOwner)
+	w = httptest.NewRecorder()
+	suite.Server.ServeHTTP(w, headOwner)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+func (suite *TestSuite) TestGCTusUploads() {
+	require.Nil(suite.T(), os.MkdirAll(suite.Service.tusDir(), 0755))
+	u := &tusUpload{ID: "staleUpload", Path: "p", User: "test", Length: 1, UpdatedAt: time.Now().Add(-48 * time.Hour).Unix()}
+	data, err := json.Marshal(u)
+	require.Nil(suite.T(), err)
+	require.Nil(suite.T(), ioutil.WriteFile(suite.Service.tusStatePath(u.ID), data, 0644))
+
+	suite.Service.gcTusUploads()
+
+	_, err = suite.Service.loadTusUpload(u.ID)
+	require.NotNil(suite.T(), err)
+}
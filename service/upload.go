@@ -0,0 +1,29 @@
+package service
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Upload handles PUT requests against /upload/{path} and stores the
+// request body as the BLOB at path.
+func (s *Service) Upload(w http.ResponseWriter, r *http.Request) {
+	pathspec := mux.Vars(r)["path"]
+
+	if s.negotiateTransfer(w, r, pathspec, true) {
+		return
+	}
+
+	var body io.Reader = r.Body
+	if s.Config.General.RequestBodyMaxSize > 0 {
+		body = io.LimitReader(r.Body, s.Config.General.RequestBodyMaxSize)
+	}
+
+	if err := s.DataController.UploadBLOB(pathspec, body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}